@@ -0,0 +1,28 @@
+// Command nfs_exporter serves NFS client mountstats as Prometheus metrics.
+package main
+
+import (
+    "flag"
+    "log"
+    "net/http"
+
+    "github.com/prometheus/client_golang/prometheus"
+    "github.com/prometheus/client_golang/prometheus/promhttp"
+
+    nfsprometheus "github.com/selfishman/nfsstats/prometheus"
+)
+
+func main() {
+    path := flag.String("mountstats.path", nfsprometheus.DefaultPath, "Path to the mountstats file to read.")
+    listenAddress := flag.String("web.listen-address", ":9520", "Address to listen on for telemetry.")
+    metricsPath := flag.String("web.telemetry-path", "/metrics", "Path under which to expose metrics.")
+    flag.Parse()
+
+    registry := prometheus.NewRegistry()
+    registry.MustRegister(nfsprometheus.NewCollector(*path))
+
+    http.Handle(*metricsPath, promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+
+    log.Printf("listening on %s", *listenAddress)
+    log.Fatal(http.ListenAndServe(*listenAddress, nil))
+}
@@ -0,0 +1,204 @@
+package nfsstats
+
+import (
+    "time"
+)
+
+// NFSMountDelta is the per-second rate of change between two NFSMount
+// snapshots of the same mount, as produced by (*NFSMount).Diff or
+// DiffSnapshots.
+type NFSMountDelta struct {
+    Device string
+    Mountpoint string
+    Version uint64
+    Elapsed time.Duration
+    Byte ByteCountersDelta
+    Event EventCountersDelta
+    Operation map[string]*OperationCountersDelta
+    Transport TransportCountersDelta
+}
+
+// ByteCountersDelta is the per-second rate of change between two
+// ByteCounters snapshots.
+type ByteCountersDelta struct {
+    NormalReadBytes, NormalWriteBytes, DirectReadBytes, DirectWriteBytes,
+    ServerReadBytes, ServerWriteBytes, ReadPages, WritePages float64
+}
+
+// EventCountersDelta is the per-second rate of change between two
+// EventCounters snapshots.
+type EventCountersDelta struct {
+    InodeRevalidate, DentryRevalidate, DataInvalidate, AttrInvalidate,
+    VFSOpen, VFSLookup, VFSAccess, VFSUpdatePage, VFSReadPage, VFSReadPages,
+    VFSWritePage, VFSWritePages, VFSGetDents, VFSSetAttr, VFSFlush, VFSSync,
+    VFSLock, VFSRelease, CongestionWait, SetAttrTrunc, ExtendWrite,
+    SillyRename, ShortRead, ShortWrite, Delay, PNFSRead, PNFSWrite float64
+}
+
+// OperationCountersDelta is the per-second rate of change between two
+// OperationCounters snapshots.
+type OperationCountersDelta struct {
+    Requests, Transmissions, Timeouts, BytesSent, BytesReceived, TotalQueueTime,
+    TotalResponseTime, TotalExecutionTime float64
+}
+
+// TransportCountersDelta is the per-second rate of change between two
+// TransportCounters snapshots. SourcePort, MaxSlotsUsed, and Protocol
+// describe transport identity rather than activity, so they are carried
+// over from the current snapshot unchanged instead of being rated.
+type TransportCountersDelta struct {
+    Protocol string
+    SourcePort, MaxSlotsUsed uint64
+    BindCount, ConnectCount, ConnectTime, IdleTime, RPCSends,
+    RPCReceives, BadTransactionIDs, RequestUtilization, BacklogUtilization,
+    SendingQueueUtilization, PendingQueueUtilization float64
+}
+
+// rate returns (curr-prev)/elapsed, clamping to zero instead of going
+// negative when a counter has wrapped around or been reset.
+func rate(curr, prev uint64, elapsed float64) float64 {
+    if curr < prev || elapsed <= 0 { return 0 }
+    return float64(curr-prev) / elapsed
+}
+
+// Diff computes the per-second rate of change of each field in b relative
+// to prev over elapsed.
+func (b ByteCounters) Diff(prev ByteCounters, elapsed time.Duration) ByteCountersDelta {
+    seconds := elapsed.Seconds()
+    return ByteCountersDelta {
+        NormalReadBytes: rate(b.NormalReadBytes, prev.NormalReadBytes, seconds),
+        NormalWriteBytes: rate(b.NormalWriteBytes, prev.NormalWriteBytes, seconds),
+        DirectReadBytes: rate(b.DirectReadBytes, prev.DirectReadBytes, seconds),
+        DirectWriteBytes: rate(b.DirectWriteBytes, prev.DirectWriteBytes, seconds),
+        ServerReadBytes: rate(b.ServerReadBytes, prev.ServerReadBytes, seconds),
+        ServerWriteBytes: rate(b.ServerWriteBytes, prev.ServerWriteBytes, seconds),
+        ReadPages: rate(b.ReadPages, prev.ReadPages, seconds),
+        WritePages: rate(b.WritePages, prev.WritePages, seconds),
+    }
+}
+
+// Diff computes the per-second rate of change of each field in e relative
+// to prev over elapsed.
+func (e EventCounters) Diff(prev EventCounters, elapsed time.Duration) EventCountersDelta {
+    seconds := elapsed.Seconds()
+    return EventCountersDelta {
+        InodeRevalidate: rate(e.InodeRevalidate, prev.InodeRevalidate, seconds),
+        DentryRevalidate: rate(e.DentryRevalidate, prev.DentryRevalidate, seconds),
+        DataInvalidate: rate(e.DataInvalidate, prev.DataInvalidate, seconds),
+        AttrInvalidate: rate(e.AttrInvalidate, prev.AttrInvalidate, seconds),
+        VFSOpen: rate(e.VFSOpen, prev.VFSOpen, seconds),
+        VFSLookup: rate(e.VFSLookup, prev.VFSLookup, seconds),
+        VFSAccess: rate(e.VFSAccess, prev.VFSAccess, seconds),
+        VFSUpdatePage: rate(e.VFSUpdatePage, prev.VFSUpdatePage, seconds),
+        VFSReadPage: rate(e.VFSReadPage, prev.VFSReadPage, seconds),
+        VFSReadPages: rate(e.VFSReadPages, prev.VFSReadPages, seconds),
+        VFSWritePage: rate(e.VFSWritePage, prev.VFSWritePage, seconds),
+        VFSWritePages: rate(e.VFSWritePages, prev.VFSWritePages, seconds),
+        VFSGetDents: rate(e.VFSGetDents, prev.VFSGetDents, seconds),
+        VFSSetAttr: rate(e.VFSSetAttr, prev.VFSSetAttr, seconds),
+        VFSFlush: rate(e.VFSFlush, prev.VFSFlush, seconds),
+        VFSSync: rate(e.VFSSync, prev.VFSSync, seconds),
+        VFSLock: rate(e.VFSLock, prev.VFSLock, seconds),
+        VFSRelease: rate(e.VFSRelease, prev.VFSRelease, seconds),
+        CongestionWait: rate(e.CongestionWait, prev.CongestionWait, seconds),
+        SetAttrTrunc: rate(e.SetAttrTrunc, prev.SetAttrTrunc, seconds),
+        ExtendWrite: rate(e.ExtendWrite, prev.ExtendWrite, seconds),
+        SillyRename: rate(e.SillyRename, prev.SillyRename, seconds),
+        ShortRead: rate(e.ShortRead, prev.ShortRead, seconds),
+        ShortWrite: rate(e.ShortWrite, prev.ShortWrite, seconds),
+        Delay: rate(e.Delay, prev.Delay, seconds),
+        PNFSRead: rate(e.PNFSRead, prev.PNFSRead, seconds),
+        PNFSWrite: rate(e.PNFSWrite, prev.PNFSWrite, seconds),
+    }
+}
+
+// Diff computes the per-second rate of change of each field in o relative
+// to prev over elapsed.
+func (o OperationCounters) Diff(prev OperationCounters, elapsed time.Duration) OperationCountersDelta {
+    seconds := elapsed.Seconds()
+    return OperationCountersDelta {
+        Requests: rate(o.Requests, prev.Requests, seconds),
+        Transmissions: rate(o.Transmissions, prev.Transmissions, seconds),
+        Timeouts: rate(o.Timeouts, prev.Timeouts, seconds),
+        BytesSent: rate(o.BytesSent, prev.BytesSent, seconds),
+        BytesReceived: rate(o.BytesReceived, prev.BytesReceived, seconds),
+        TotalQueueTime: rate(o.TotalQueueTime, prev.TotalQueueTime, seconds),
+        TotalResponseTime: rate(o.TotalResponseTime, prev.TotalResponseTime, seconds),
+        TotalExecutionTime: rate(o.TotalExecutionTime, prev.TotalExecutionTime, seconds),
+    }
+}
+
+// Diff computes the per-second rate of change of each field in t relative
+// to prev over elapsed. Protocol, SourcePort, and MaxSlotsUsed are carried
+// over from t unchanged since they identify the transport rather than
+// accumulate over time.
+func (t TransportCounters) Diff(prev TransportCounters, elapsed time.Duration) TransportCountersDelta {
+    seconds := elapsed.Seconds()
+    return TransportCountersDelta {
+        Protocol: t.Protocol,
+        SourcePort: t.SourcePort,
+        MaxSlotsUsed: t.MaxSlotsUsed,
+        BindCount: rate(t.BindCount, prev.BindCount, seconds),
+        ConnectCount: rate(t.ConnectCount, prev.ConnectCount, seconds),
+        ConnectTime: rate(t.ConnectTime, prev.ConnectTime, seconds),
+        IdleTime: rate(t.IdleTime, prev.IdleTime, seconds),
+        RPCSends: rate(t.RPCSends, prev.RPCSends, seconds),
+        RPCReceives: rate(t.RPCReceives, prev.RPCReceives, seconds),
+        BadTransactionIDs: rate(t.BadTransactionIDs, prev.BadTransactionIDs, seconds),
+        RequestUtilization: rate(t.RequestUtilization, prev.RequestUtilization, seconds),
+        BacklogUtilization: rate(t.BacklogUtilization, prev.BacklogUtilization, seconds),
+        SendingQueueUtilization: rate(t.SendingQueueUtilization, prev.SendingQueueUtilization, seconds),
+        PendingQueueUtilization: rate(t.PendingQueueUtilization, prev.PendingQueueUtilization, seconds),
+    }
+}
+
+// Diff computes the per-second rate of change between m and prev, which
+// must be snapshots of the same mount taken elapsed apart. Per-operation
+// deltas are only computed for operations present in both snapshots.
+func (m *NFSMount) Diff(prev *NFSMount, elapsed time.Duration) *NFSMountDelta {
+    delta := &NFSMountDelta {
+        Device: m.Device,
+        Mountpoint: m.Mountpoint,
+        Version: m.Version,
+        Elapsed: elapsed,
+        Operation: make(map[string]*OperationCountersDelta),
+    }
+
+    if m.Statistics == nil || prev.Statistics == nil {
+        return delta
+    }
+
+    delta.Byte = m.Statistics.Byte.Diff(prev.Statistics.Byte, elapsed)
+    delta.Event = m.Statistics.Event.Diff(prev.Statistics.Event, elapsed)
+    delta.Transport = m.Statistics.Transport.Diff(prev.Statistics.Transport, elapsed)
+
+    for op, counters := range m.Statistics.Operation {
+        prevCounters, ok := prev.Statistics.Operation[op]
+        if !ok { continue }
+
+        opDelta := counters.Diff(*prevCounters, elapsed)
+        delta.Operation[op] = &opDelta
+    }
+
+    return delta
+}
+
+// DiffSnapshots joins two slices of NFSMount snapshots of the same mounts
+// by Device+Mountpoint and returns the per-second rate of change for every
+// mount present in both curr and prev.
+func DiffSnapshots(prev, curr []*NFSMount, elapsed time.Duration) []*NFSMountDelta {
+    prevByKey := make(map[string]*NFSMount, len(prev))
+    for _, mount := range prev {
+        prevByKey[mount.Device+"\x00"+mount.Mountpoint] = mount
+    }
+
+    var deltas []*NFSMountDelta
+    for _, mount := range curr {
+        prevMount, ok := prevByKey[mount.Device+"\x00"+mount.Mountpoint]
+        if !ok { continue }
+
+        deltas = append(deltas, mount.Diff(prevMount, elapsed))
+    }
+
+    return deltas
+}
@@ -0,0 +1,93 @@
+package nfsstats
+
+import (
+    "testing"
+    "time"
+)
+
+func TestRate(t *testing.T) {
+    tests := []struct {
+        name string
+        curr, prev uint64
+        elapsed float64
+        want float64
+    }{
+        {name: "normal increase", curr: 110, prev: 100, elapsed: 10, want: 1},
+        {name: "no change", curr: 100, prev: 100, elapsed: 10, want: 0},
+        {name: "wraparound clamps to zero", curr: 5, prev: 100, elapsed: 10, want: 0},
+        {name: "zero elapsed clamps to zero", curr: 110, prev: 100, elapsed: 0, want: 0},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            got := rate(tt.curr, tt.prev, tt.elapsed)
+            if got != tt.want {
+                t.Errorf("rate(%d, %d, %v) = %v, want %v", tt.curr, tt.prev, tt.elapsed, got, tt.want)
+            }
+        })
+    }
+}
+
+func TestNFSMountDiff_Wraparound(t *testing.T) {
+    prev := &NFSMount{
+        Device: "host:/export",
+        Mountpoint: "/mnt",
+        Statistics: &Statistics{
+            Byte: ByteCounters{NormalReadBytes: 1000},
+            Operation: map[string]*OperationCounters{
+                "READ": {Requests: 500},
+            },
+        },
+    }
+
+    // The counters in curr are lower than prev, as if the server had
+    // rebooted and reset its counters between snapshots.
+    curr := &NFSMount{
+        Device: "host:/export",
+        Mountpoint: "/mnt",
+        Statistics: &Statistics{
+            Byte: ByteCounters{NormalReadBytes: 10},
+            Operation: map[string]*OperationCounters{
+                "READ": {Requests: 5},
+            },
+        },
+    }
+
+    delta := curr.Diff(prev, 10*time.Second)
+
+    if delta.Byte.NormalReadBytes != 0 {
+        t.Errorf("Byte.NormalReadBytes = %v, want 0", delta.Byte.NormalReadBytes)
+    }
+    if delta.Operation["READ"].Requests != 0 {
+        t.Errorf("Operation[READ].Requests = %v, want 0", delta.Operation["READ"].Requests)
+    }
+}
+
+func TestDiffSnapshots(t *testing.T) {
+    prev := []*NFSMount{
+        {
+            Device: "host:/export", Mountpoint: "/mnt",
+            Statistics: &Statistics{Byte: ByteCounters{NormalReadBytes: 100}},
+        },
+    }
+    curr := []*NFSMount{
+        {
+            Device: "host:/export", Mountpoint: "/mnt",
+            Statistics: &Statistics{Byte: ByteCounters{NormalReadBytes: 200}},
+        },
+        {
+            // Not present in prev, so it should be skipped rather than
+            // producing a bogus delta.
+            Device: "host:/other", Mountpoint: "/mnt2",
+            Statistics: &Statistics{Byte: ByteCounters{NormalReadBytes: 50}},
+        },
+    }
+
+    deltas := DiffSnapshots(prev, curr, 10*time.Second)
+    if len(deltas) != 1 {
+        t.Fatalf("got %d deltas, want 1", len(deltas))
+    }
+    if deltas[0].Byte.NormalReadBytes != 10 {
+        t.Errorf("NormalReadBytes = %v, want 10", deltas[0].Byte.NormalReadBytes)
+    }
+}
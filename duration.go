@@ -0,0 +1,64 @@
+package nfsstats
+
+import (
+    "time"
+)
+
+// AgeDuration returns Age as a time.Duration. The mountstats "age" field is
+// reported in whole seconds.
+func (s *Statistics) AgeDuration() time.Duration {
+    return time.Duration(s.Age) * time.Second
+}
+
+// ConnectDuration returns ConnectTime as a time.Duration. ConnectTime is
+// reported in milliseconds.
+func (t TransportCounters) ConnectDuration() time.Duration {
+    return time.Duration(t.ConnectTime) * time.Millisecond
+}
+
+// IdleDuration returns IdleTime as a time.Duration. IdleTime is reported in
+// milliseconds.
+func (t TransportCounters) IdleDuration() time.Duration {
+    return time.Duration(t.IdleTime) * time.Millisecond
+}
+
+// QueueDuration returns TotalQueueTime as a time.Duration. TotalQueueTime is
+// reported in milliseconds.
+func (o OperationCounters) QueueDuration() time.Duration {
+    return time.Duration(o.TotalQueueTime) * time.Millisecond
+}
+
+// ResponseDuration returns TotalResponseTime as a time.Duration.
+// TotalResponseTime is reported in milliseconds.
+func (o OperationCounters) ResponseDuration() time.Duration {
+    return time.Duration(o.TotalResponseTime) * time.Millisecond
+}
+
+// ExecutionDuration returns TotalExecutionTime as a time.Duration.
+// TotalExecutionTime is reported in milliseconds.
+func (o OperationCounters) ExecutionDuration() time.Duration {
+    return time.Duration(o.TotalExecutionTime) * time.Millisecond
+}
+
+// AvgRTT returns the average round-trip time per request: ResponseDuration
+// divided by Requests. It returns zero if no requests have been made.
+func (o OperationCounters) AvgRTT() time.Duration {
+    if o.Requests == 0 { return 0 }
+    return o.ResponseDuration() / time.Duration(o.Requests)
+}
+
+// AvgQueueTime returns the average time spent waiting for a free RPC slot
+// per request: QueueDuration divided by Requests. It returns zero if no
+// requests have been made.
+func (o OperationCounters) AvgQueueTime() time.Duration {
+    if o.Requests == 0 { return 0 }
+    return o.QueueDuration() / time.Duration(o.Requests)
+}
+
+// AvgExecutionTime returns the average execution time per request:
+// ExecutionDuration divided by Requests. It returns zero if no requests
+// have been made.
+func (o OperationCounters) AvgExecutionTime() time.Duration {
+    if o.Requests == 0 { return 0 }
+    return o.ExecutionDuration() / time.Duration(o.Requests)
+}
@@ -1,9 +1,10 @@
 package nfsstats
 
 // NFS mountstat documentation from http://www.fsl.cs.stonybrook.edu/~mchen/mountstat-format.txt
-// We will only support statvers=1.1
+// We support statvers=1.0 and statvers=1.1
 import (
     "bufio"
+    "fmt"
     "io"
     "strconv"
     "strings"
@@ -15,6 +16,7 @@ type NFSMount struct {
     Mountpoint string
     Statistics *Statistics
     Version uint64
+    StatVersion string
 }
 
 // NFS statistics wrapper object
@@ -105,21 +107,117 @@ type OperationCounters struct {
 // Transport (linux/net/sunrpc/xprtsock.c: xs_tcp_print_stats)
 // https://git.kernel.org/pub/scm/linux/kernel/git/torvalds/linux.git/tree/net/sunrpc/xprtsock.c#n2600
 type TransportCounters struct {
+    Protocol string
     SourcePort, BindCount, ConnectCount, ConnectTime, IdleTime, RPCSends,
     RPCReceives, BadTransactionIDs, RequestUtilization, BacklogUtilization, MaxSlotsUsed,
     SendingQueueUtilization, PendingQueueUtilization uint64
 }
 
+// ParseError describes a single malformed line (or field within a line)
+// encountered while parsing a mountstats file.
+type ParseError struct {
+    Line int
+    Field int
+    Text string
+    Err error
+}
+
+func (e *ParseError) Error() string {
+    return fmt.Sprintf("nfsstats: parse error at line %d, field %d (%q): %v", e.Line, e.Field, e.Text, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+    return e.Err
+}
+
+// ParseMode selects how Parse/ParseWithOptions react to malformed input.
+type ParseMode int
+
+const (
+    // ParseModeSilent reproduces the historical behavior of this package:
+    // malformed fields keep the value strconv.ParseUint returns for them
+    // (zero, or the maximum uint64 when out of range) and short/long lines
+    // are skipped without being reported anywhere.
+    ParseModeSilent ParseMode = iota
+
+    // ParseModeLenient collects every ParseError encountered into the
+    // error slice returned by ParseWithOptions, but keeps parsing the
+    // rest of the file.
+    ParseModeLenient
+
+    // ParseModeStrict aborts parsing as soon as the first ParseError is
+    // encountered and returns it.
+    ParseModeStrict
+)
+
+// ParseOptions configures ParseWithOptions.
+type ParseOptions struct {
+    Mode ParseMode
+}
+
+// parseState threads the scanner, the current line number, and the
+// configured ParseOptions through the recursive-descent parsing helpers.
+type parseState struct {
+    scanner *bufio.Scanner
+    line int
+    opts ParseOptions
+    errs []error
+}
+
+func (ps *parseState) scan() bool {
+    if !ps.scanner.Scan() { return false }
+    ps.line++
+    return true
+}
+
+func (ps *parseState) fields() []string {
+    return strings.Fields(string(ps.scanner.Bytes()))
+}
+
+// handleError applies the configured ParseMode to a ParseError. A non-nil
+// return value means the caller must abort parsing immediately.
+func (ps *parseState) handleError(err *ParseError) error {
+    switch ps.opts.Mode {
+        case ParseModeStrict:
+            return err
+        case ParseModeLenient:
+            ps.errs = append(ps.errs, err)
+            return nil
+        default:
+            return nil
+    }
+}
+
+// lineError builds a ParseError for an entire malformed line (as opposed
+// to a single bad field) and runs it through handleError.
+func (ps *parseState) lineError(fields []string, want int) error {
+    err := &ParseError {
+        Line: ps.line,
+        Field: -1,
+        Text: strings.Join(fields, " "),
+        Err: fmt.Errorf("expected %d fields, got %d", want, len(fields)),
+    }
+    return ps.handleError(err)
+}
+
 // Parse the contents of a /proc/:pid/mountstats file and return stats about each NFS mount
 func Parse(reader io.Reader) ([]*NFSMount, error) {
+    nfsMounts, _, err := ParseWithOptions(reader, ParseOptions{Mode: ParseModeSilent})
+    return nfsMounts, err
+}
+
+// ParseWithOptions parses the contents of a /proc/:pid/mountstats file like
+// Parse, but lets the caller choose how malformed lines are handled via
+// opts.Mode. In ParseModeLenient the second return value holds every
+// ParseError encountered; in the other modes it is always nil.
+func ParseWithOptions(reader io.Reader, opts ParseOptions) ([]*NFSMount, []error, error) {
     var nfsMounts []*NFSMount
 
-    scanner := bufio.NewScanner(reader)
+    ps := &parseState{scanner: bufio.NewScanner(reader), opts: opts}
 
     // Loop through each line and extract mount data
-    for scanner.Scan() {
-        // Split each line on spaces
-        fields := strings.Fields(string(scanner.Bytes()))
+    for ps.scan() {
+        fields := ps.fields()
 
         // Skip empty lines
         if len(fields) == 0 { continue }
@@ -129,10 +227,10 @@ func Parse(reader io.Reader) ([]*NFSMount, error) {
         // Most mounts have 8 fields, mounts with stats (like NFS) have 9 fields and the last is "statsver={version}"
 
         if fields[0] == "device" {
-            // Skip mounts that are not NFS and not version 1.1
+            // Skip mounts that are not NFS and not version 1.0/1.1
             if len(fields) != 9 { continue }
             if fields[7] != "nfs" && fields[7] != "nfs4" { continue }
-            if fields[8] != "statvers=1.1" { continue }
+            if fields[8] != "statvers=1.0" && fields[8] != "statvers=1.1" { continue }
 
             // Determine NFS version (8th element)
             nfsVersion := 3
@@ -140,17 +238,20 @@ func Parse(reader io.Reader) ([]*NFSMount, error) {
                 nfsVersion = 4
             }
 
+            statVersion := strings.TrimPrefix(fields[8], "statvers=")
+
             // Save the basic mount info
             nfsMount := &NFSMount {
                 Device: fields[1],
                 Mountpoint: fields[4],
                 Version: uint64(nfsVersion),
+                StatVersion: statVersion,
             }
 
             // Capture statistics
-            statistics, err := parseStatistics(scanner)
+            statistics, err := parseStatistics(ps, statVersion)
             if err != nil {
-                return nil, err
+                return nil, ps.errs, err
             }
             nfsMount.Statistics = statistics
 
@@ -159,7 +260,11 @@ func Parse(reader io.Reader) ([]*NFSMount, error) {
         }
     }
 
-    return nfsMounts, scanner.Err()
+    if err := ps.scanner.Err(); err != nil {
+        return nil, ps.errs, err
+    }
+
+    return nfsMounts, ps.errs, nil
 }
 
 func NewStatistics() *Statistics {
@@ -168,13 +273,12 @@ func NewStatistics() *Statistics {
     return &statistics
 }
 
-func parseStatistics(scanner *bufio.Scanner) (*Statistics, error) {
+func parseStatistics(ps *parseState, statVersion string) (*Statistics, error) {
     statistics := NewStatistics()
 
     // Extract each metric type
-    for scanner.Scan() {
-        // Split each line on spaces
-        fields := strings.Fields(string(scanner.Bytes()))
+    for ps.scan() {
+        fields := ps.fields()
 
         // The stats are done or we need to move on to per-operation stats
         // Either way we leave the loop
@@ -186,13 +290,23 @@ func parseStatistics(scanner *bufio.Scanner) (*Statistics, error) {
         // Determine stats type and parse it
         switch fields[0] {
             case "age:":
-                statistics.Age, _ = strconv.ParseUint(fields[1], 10, 64)
+                age, err := strconv.ParseUint(fields[1], 10, 64)
+                if err != nil {
+                    if handleErr := ps.handleError(&ParseError{Line: ps.line, Field: 1, Text: fields[1], Err: err}); handleErr != nil {
+                        return nil, handleErr
+                    }
+                }
+                statistics.Age = age
 
             case "bytes:":
                 // There must be 9 byte elements
-                if len(fields) != 9 { continue }
+                if len(fields) != 9 {
+                    if err := ps.lineError(fields, 9); err != nil { return nil, err }
+                    continue
+                }
 
-                elements := makeUint64(fields[1:])
+                elements, err := makeUint64(ps, fields[1:], 1)
+                if err != nil { return nil, err }
 
                 statistics.Byte = ByteCounters {
                     NormalReadBytes: elements[0],
@@ -207,9 +321,13 @@ func parseStatistics(scanner *bufio.Scanner) (*Statistics, error) {
 
             case "events:":
                 // There must be 28 event elements
-                if len(fields) != 28 { continue }
+                if len(fields) != 28 {
+                    if err := ps.lineError(fields, 28); err != nil { return nil, err }
+                    continue
+                }
 
-                elements := makeUint64(fields[1:])
+                elements, err := makeUint64(ps, fields[1:], 1)
+                if err != nil { return nil, err }
 
                 statistics.Event = EventCounters {
                     InodeRevalidate: elements[0],
@@ -242,37 +360,77 @@ func parseStatistics(scanner *bufio.Scanner) (*Statistics, error) {
                 }
 
             case "xprt:":
-                // We only parse it if the transport is TCP
-                // Based on docs, it looks like UDP doesn't report this line
-                // FIXME: check against udp
-                if fields[1] != "tcp" { continue }
-
-                // There must be 15 transport elements
-                if len(fields) != 15 { continue }
-
-                elements := makeUint64(fields[2:])
-
-                statistics.Transport = TransportCounters {
-                    SourcePort: elements[0],
-                    BindCount: elements[1],
-                    ConnectCount: elements[2],
-                    ConnectTime: elements[3],
-                    IdleTime: elements[4],
-                    RPCSends: elements[5],
-                    RPCReceives: elements[6],
-                    BadTransactionIDs: elements[7],
-                    RequestUtilization: elements[8],
-                    BacklogUtilization: elements[9],
-                    MaxSlotsUsed: elements[10],
-                    SendingQueueUtilization: elements[11],
-                    PendingQueueUtilization: elements[12],
+                protocol := fields[1]
+                switch protocol {
+                    case "udp":
+                        // 7 elements on statvers=1.0, 10 on statvers=1.1
+                        if len(fields) != 9 && len(fields) != 12 {
+                            if err := ps.lineError(fields, 12); err != nil { return nil, err }
+                            continue
+                        }
+
+                        elements, err := makeUint64(ps, fields[2:], 2)
+                        if err != nil { return nil, err }
+
+                        statistics.Transport = TransportCounters {
+                            Protocol: protocol,
+                            SourcePort: elements[0],
+                            BindCount: elements[1],
+                            RPCSends: elements[2],
+                            RPCReceives: elements[3],
+                            BadTransactionIDs: elements[4],
+                            RequestUtilization: elements[5],
+                            BacklogUtilization: elements[6],
+                        }
+
+                        if len(elements) == 10 {
+                            statistics.Transport.MaxSlotsUsed = elements[7]
+                            statistics.Transport.SendingQueueUtilization = elements[8]
+                            statistics.Transport.PendingQueueUtilization = elements[9]
+                        }
+
+                    case "tcp":
+                        // 10 elements on statvers=1.0, 13 on statvers=1.1
+                        if len(fields) != 12 && len(fields) != 15 {
+                            if err := ps.lineError(fields, 15); err != nil { return nil, err }
+                            continue
+                        }
+
+                        elements, err := makeUint64(ps, fields[2:], 2)
+                        if err != nil { return nil, err }
+
+                        statistics.Transport = TransportCounters {
+                            Protocol: protocol,
+                            SourcePort: elements[0],
+                            BindCount: elements[1],
+                            ConnectCount: elements[2],
+                            ConnectTime: elements[3],
+                            IdleTime: elements[4],
+                            RPCSends: elements[5],
+                            RPCReceives: elements[6],
+                            BadTransactionIDs: elements[7],
+                            RequestUtilization: elements[8],
+                            BacklogUtilization: elements[9],
+                        }
+
+                        if len(elements) == 13 {
+                            statistics.Transport.MaxSlotsUsed = elements[10]
+                            statistics.Transport.SendingQueueUtilization = elements[11]
+                            statistics.Transport.PendingQueueUtilization = elements[12]
+                        }
+
+                    default:
+                        // Unknown transport protocol, skip it
+                        continue
                 }
         }
     }
 
     // Extract per-operation stats
-    parseOperations(scanner, statistics)
-    if err := scanner.Err(); err != nil {
+    if err := parseOperations(ps, statistics, statVersion); err != nil {
+        return nil, err
+    }
+    if err := ps.scanner.Err(); err != nil {
         return nil, err
     }
 
@@ -280,21 +438,30 @@ func parseStatistics(scanner *bufio.Scanner) (*Statistics, error) {
     return statistics, nil
 }
 
-func parseOperations(scanner *bufio.Scanner, statistics *Statistics) () {
+func parseOperations(ps *parseState, statistics *Statistics, statVersion string) error {
+    // statvers=1.0 lacks the TotalExecutionTime metric, so each op line has
+    // 7 values instead of 8
+    expectedFields := 9
+    if statVersion == "1.0" { expectedFields = 8 }
+
     // Extract each metric type
-    for scanner.Scan() {
-        // Split each line on spaces
-        fields := strings.Fields(string(scanner.Bytes()))
+    for ps.scan() {
+        fields := ps.fields()
         // Bail if the line is empty or a device line is encountered
         if len(fields) == 0 || fields[0] == "device" { break }
         // Skip malformed lines
-        if len(fields) != 9 { continue }
+        if len(fields) != expectedFields {
+            if err := ps.lineError(fields, expectedFields); err != nil { return err }
+            continue
+        }
 
         // Store the values
         opName := strings.TrimSuffix(fields[0], ":")
 
-        elements := makeUint64(fields[1:])
-        statistics.Operation[opName] = &OperationCounters {
+        elements, err := makeUint64(ps, fields[1:], 1)
+        if err != nil { return err }
+
+        operation := &OperationCounters {
             Requests: elements[0],
             Transmissions: elements[1],
             Timeouts: elements[2],
@@ -302,22 +469,37 @@ func parseOperations(scanner *bufio.Scanner, statistics *Statistics) () {
             BytesReceived: elements[4],
             TotalQueueTime: elements[5],
             TotalResponseTime: elements[6],
-            TotalExecutionTime: elements[7],
         }
+
+        if len(elements) == 8 {
+            operation.TotalExecutionTime = elements[7]
+        }
+
+        statistics.Operation[opName] = operation
     }
 
-    return
+    return nil
 }
 
-func makeUint64(fields []string) []uint64 {
-    // Iterate over each field element and re-cast it from string to uint64
+// makeUint64 converts each string in fields to a uint64. fieldOffset is the
+// index of fields[0] within the original line, used to report accurate
+// field positions in ParseErrors. Values that fail to parse are recorded as
+// strconv.ParseUint returns them (zero for a syntax error, the maximum
+// uint64 for an out-of-range value) so the returned slice always has
+// len(fields) elements.
+func makeUint64(ps *parseState, fields []string, fieldOffset int) ([]uint64, error) {
     elements := make([]uint64, 0, len(fields))
-    for _, element := range fields {
-        val, _ := strconv.ParseUint(element, 10, 64)
+    for i, field := range fields {
+        val, err := strconv.ParseUint(field, 10, 64)
+        if err != nil {
+            if handleErr := ps.handleError(&ParseError{Line: ps.line, Field: fieldOffset + i, Text: field, Err: err}); handleErr != nil {
+                return nil, handleErr
+            }
+        }
         elements = append(elements, val)
     }
 
-    return elements
+    return elements, nil
 }
 
 // vim:ft=go:et:ts=4:sw=4:sts=4:
@@ -0,0 +1,160 @@
+package nfsstats
+
+import (
+    "strings"
+    "testing"
+)
+
+// mountstatsFixture builds a minimal single-mount mountstats body using the
+// given statvers and xprt/per-op lines, which vary in field count across
+// stat versions and transports.
+func mountstatsFixture(statVersion, xprtLine, perOpLine string) string {
+    return strings.Join([]string{
+        "device 192.168.1.1:/export mounted on /mnt with fstype nfs statvers=" + statVersion,
+        "age: 100",
+        "bytes: 1 2 3 4 5 6 7 8",
+        "events: " + strings.Repeat("1 ", 26) + "1",
+        xprtLine,
+        "per-op statistics",
+        perOpLine,
+        "",
+    }, "\n")
+}
+
+func TestParseWithOptions_StatverTransportCombos(t *testing.T) {
+    tests := []struct {
+        name string
+        statVersion string
+        xprtLine string
+        perOpLine string
+        wantProtocol string
+        wantMaxSlotsUsed uint64
+        wantExecTime uint64
+    }{
+        {
+            name: "tcp/1.1",
+            statVersion: "1.1",
+            xprtLine: "xprt: tcp 0 1 2 3 4 5 6 7 8 9 10 11 12",
+            perOpLine: "READ: 1 2 3 4 5 6 7 8",
+            wantProtocol: "tcp",
+            wantMaxSlotsUsed: 10,
+            wantExecTime: 8,
+        },
+        {
+            name: "tcp/1.0",
+            statVersion: "1.0",
+            xprtLine: "xprt: tcp 0 1 2 3 4 5 6 7 8 9",
+            perOpLine: "READ: 1 2 3 4 5 6 7",
+            wantProtocol: "tcp",
+            wantMaxSlotsUsed: 0,
+            wantExecTime: 0,
+        },
+        {
+            name: "udp/1.1",
+            statVersion: "1.1",
+            xprtLine: "xprt: udp 0 1 2 3 4 5 6 7 8 9",
+            perOpLine: "READ: 1 2 3 4 5 6 7 8",
+            wantProtocol: "udp",
+            wantMaxSlotsUsed: 7,
+            wantExecTime: 8,
+        },
+        {
+            name: "udp/1.0",
+            statVersion: "1.0",
+            xprtLine: "xprt: udp 0 1 2 3 4 5 6",
+            perOpLine: "READ: 1 2 3 4 5 6 7",
+            wantProtocol: "udp",
+            wantMaxSlotsUsed: 0,
+            wantExecTime: 0,
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            body := mountstatsFixture(tt.statVersion, tt.xprtLine, tt.perOpLine)
+            mounts, _, err := ParseWithOptions(strings.NewReader(body), ParseOptions{Mode: ParseModeStrict})
+            if err != nil {
+                t.Fatalf("ParseWithOptions: %v", err)
+            }
+            if len(mounts) != 1 {
+                t.Fatalf("got %d mounts, want 1", len(mounts))
+            }
+
+            mount := mounts[0]
+            if mount.StatVersion != tt.statVersion {
+                t.Errorf("StatVersion = %q, want %q", mount.StatVersion, tt.statVersion)
+            }
+
+            transport := mount.Statistics.Transport
+            if transport.Protocol != tt.wantProtocol {
+                t.Errorf("Protocol = %q, want %q", transport.Protocol, tt.wantProtocol)
+            }
+            if transport.MaxSlotsUsed != tt.wantMaxSlotsUsed {
+                t.Errorf("MaxSlotsUsed = %d, want %d", transport.MaxSlotsUsed, tt.wantMaxSlotsUsed)
+            }
+
+            op, ok := mount.Statistics.Operation["READ"]
+            if !ok {
+                t.Fatal("missing READ operation counters")
+            }
+            if op.TotalExecutionTime != tt.wantExecTime {
+                t.Errorf("TotalExecutionTime = %d, want %d", op.TotalExecutionTime, tt.wantExecTime)
+            }
+        })
+    }
+}
+
+func TestParseWithOptions_Modes(t *testing.T) {
+    // The bytes line is short (5 elements instead of 8), which is the kind
+    // of malformed line each ParseMode is meant to treat differently.
+    body := mountstatsFixture("1.1", "xprt: tcp 0 1 2 3 4 5 6 7 8 9 10 11 12", "READ: 1 2 3 4 5 6 7 8")
+    body = strings.Replace(body, "bytes: 1 2 3 4 5 6 7 8", "bytes: 1 2 3 4 5", 1)
+
+    t.Run("silent", func(t *testing.T) {
+        mounts, errs, err := ParseWithOptions(strings.NewReader(body), ParseOptions{Mode: ParseModeSilent})
+        if err != nil {
+            t.Fatalf("unexpected error: %v", err)
+        }
+        if errs != nil {
+            t.Fatalf("got errs %v, want nil", errs)
+        }
+        if len(mounts) != 1 {
+            t.Fatalf("got %d mounts, want 1", len(mounts))
+        }
+        if mounts[0].Statistics.Byte != (ByteCounters{}) {
+            t.Errorf("Byte = %+v, want zero value for skipped line", mounts[0].Statistics.Byte)
+        }
+    })
+
+    t.Run("lenient", func(t *testing.T) {
+        mounts, errs, err := ParseWithOptions(strings.NewReader(body), ParseOptions{Mode: ParseModeLenient})
+        if err != nil {
+            t.Fatalf("unexpected error: %v", err)
+        }
+        if len(errs) != 1 {
+            t.Fatalf("got %d errs, want 1: %v", len(errs), errs)
+        }
+        if _, ok := errs[0].(*ParseError); !ok {
+            t.Errorf("errs[0] is %T, want *ParseError", errs[0])
+        }
+        if len(mounts) != 1 {
+            t.Fatalf("got %d mounts, want 1", len(mounts))
+        }
+    })
+
+    t.Run("strict", func(t *testing.T) {
+        mounts, errs, err := ParseWithOptions(strings.NewReader(body), ParseOptions{Mode: ParseModeStrict})
+        if err == nil {
+            t.Fatal("expected an error, got nil")
+        }
+        if _, ok := err.(*ParseError); !ok {
+            t.Errorf("err is %T, want *ParseError", err)
+        }
+        if errs != nil {
+            t.Errorf("got errs %v, want nil", errs)
+        }
+        if mounts != nil {
+            t.Errorf("got mounts %v, want nil", mounts)
+        }
+    })
+}
@@ -0,0 +1,268 @@
+// Package prometheus implements a prometheus.Collector that exposes
+// /proc/[pid]/mountstats as NFS client metrics.
+package prometheus
+
+import (
+    "fmt"
+    "os"
+
+    "github.com/prometheus/client_golang/prometheus"
+
+    "github.com/selfishman/nfsstats"
+)
+
+const namespace = "nfs"
+
+// DefaultPath is the mountstats file read when NewCollector is given an
+// empty path.
+const DefaultPath = "/proc/self/mountstats"
+
+// Option configures a Collector.
+type Option func(*Collector)
+
+// WithPath overrides the mountstats file read on every Collect.
+func WithPath(path string) Option {
+    return func(c *Collector) {
+        c.path = path
+    }
+}
+
+// Collector implements prometheus.Collector by re-parsing a mountstats
+// file on every scrape and emitting gauges/counters for each field in
+// nfsstats.ByteCounters, nfsstats.EventCounters, nfsstats.TransportCounters,
+// and per-operation nfsstats.OperationCounters.
+type Collector struct {
+    path string
+
+    age *prometheus.Desc
+
+    normalReadBytes, normalWriteBytes, directReadBytes, directWriteBytes,
+    serverReadBytes, serverWriteBytes, readPages, writePages *prometheus.Desc
+
+    inodeRevalidate, dentryRevalidate, dataInvalidate, attrInvalidate,
+    vfsOpen, vfsLookup, vfsAccess, vfsUpdatePage, vfsReadPage, vfsReadPages,
+    vfsWritePage, vfsWritePages, vfsGetDents, vfsSetAttr, vfsFlush, vfsSync,
+    vfsLock, vfsRelease, congestionWait, setAttrTrunc, extendWrite,
+    sillyRename, shortRead, shortWrite, delay, pnfsRead, pnfsWrite *prometheus.Desc
+
+    sourcePort, bindCount, connectCount, connectTime, idleTime, rpcSends,
+    rpcReceives, badTransactionIDs, requestUtilization, backlogUtilization,
+    maxSlotsUsed, sendingQueueUtilization, pendingQueueUtilization *prometheus.Desc
+
+    opRequests, opTransmissions, opTimeouts, opBytesSent, opBytesReceived,
+    opTotalQueueTime, opTotalResponseTime, opTotalExecutionTime,
+    opAvgRTT, opAvgQueueTime, opAvgExecutionTime,
+    opBytesSentPerOp, opBytesReceivedPerOp, opRetransmissionRatio *prometheus.Desc
+}
+
+// NewCollector returns a Collector that reads mountstats from path on every
+// Collect call. An empty path defaults to DefaultPath.
+func NewCollector(path string, opts ...Option) *Collector {
+    if path == "" {
+        path = DefaultPath
+    }
+
+    mountLabels := []string{"device", "mountpoint", "nfs_version"}
+    opLabels := append(append([]string{}, mountLabels...), "operation")
+
+    c := &Collector{
+        path: path,
+
+        age: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "age_seconds"), "Time since the NFS mount was made.", mountLabels, nil),
+
+        normalReadBytes:   prometheus.NewDesc(prometheus.BuildFQName(namespace, "byte", "normal_read_bytes_total"), "Bytes read via read(2) by applications.", mountLabels, nil),
+        normalWriteBytes:  prometheus.NewDesc(prometheus.BuildFQName(namespace, "byte", "normal_write_bytes_total"), "Bytes written via write(2) by applications.", mountLabels, nil),
+        directReadBytes:   prometheus.NewDesc(prometheus.BuildFQName(namespace, "byte", "direct_read_bytes_total"), "Bytes read from files opened with O_DIRECT.", mountLabels, nil),
+        directWriteBytes:  prometheus.NewDesc(prometheus.BuildFQName(namespace, "byte", "direct_write_bytes_total"), "Bytes written to files opened with O_DIRECT.", mountLabels, nil),
+        serverReadBytes:   prometheus.NewDesc(prometheus.BuildFQName(namespace, "byte", "server_read_bytes_total"), "Payload bytes read from the server via NFS READ.", mountLabels, nil),
+        serverWriteBytes:  prometheus.NewDesc(prometheus.BuildFQName(namespace, "byte", "server_write_bytes_total"), "Payload bytes written to the server via NFS WRITE.", mountLabels, nil),
+        readPages:         prometheus.NewDesc(prometheus.BuildFQName(namespace, "byte", "read_pages_total"), "Pages read via nfs_readpage()/nfs_readpages().", mountLabels, nil),
+        writePages:        prometheus.NewDesc(prometheus.BuildFQName(namespace, "byte", "write_pages_total"), "Pages written via the write equivalents of nfs_readpage().", mountLabels, nil),
+
+        inodeRevalidate:  prometheus.NewDesc(prometheus.BuildFQName(namespace, "event", "inode_revalidate_total"), "Inode cache revalidations.", mountLabels, nil),
+        dentryRevalidate: prometheus.NewDesc(prometheus.BuildFQName(namespace, "event", "dentry_revalidate_total"), "Dentry cache revalidations.", mountLabels, nil),
+        dataInvalidate:   prometheus.NewDesc(prometheus.BuildFQName(namespace, "event", "data_invalidate_total"), "Data cache invalidations.", mountLabels, nil),
+        attrInvalidate:   prometheus.NewDesc(prometheus.BuildFQName(namespace, "event", "attr_invalidate_total"), "Attribute cache invalidations.", mountLabels, nil),
+        vfsOpen:          prometheus.NewDesc(prometheus.BuildFQName(namespace, "event", "vfs_open_total"), "VFS open() calls.", mountLabels, nil),
+        vfsLookup:        prometheus.NewDesc(prometheus.BuildFQName(namespace, "event", "vfs_lookup_total"), "VFS lookup() calls.", mountLabels, nil),
+        vfsAccess:        prometheus.NewDesc(prometheus.BuildFQName(namespace, "event", "vfs_access_total"), "VFS access() calls.", mountLabels, nil),
+        vfsUpdatePage:    prometheus.NewDesc(prometheus.BuildFQName(namespace, "event", "vfs_updatepage_total"), "VFS updatepage() calls.", mountLabels, nil),
+        vfsReadPage:      prometheus.NewDesc(prometheus.BuildFQName(namespace, "event", "vfs_readpage_total"), "VFS readpage() calls.", mountLabels, nil),
+        vfsReadPages:     prometheus.NewDesc(prometheus.BuildFQName(namespace, "event", "vfs_readpages_total"), "VFS readpages() calls.", mountLabels, nil),
+        vfsWritePage:     prometheus.NewDesc(prometheus.BuildFQName(namespace, "event", "vfs_writepage_total"), "VFS writepage() calls.", mountLabels, nil),
+        vfsWritePages:    prometheus.NewDesc(prometheus.BuildFQName(namespace, "event", "vfs_writepages_total"), "VFS writepages() calls.", mountLabels, nil),
+        vfsGetDents:      prometheus.NewDesc(prometheus.BuildFQName(namespace, "event", "vfs_getdents_total"), "VFS getdents() calls.", mountLabels, nil),
+        vfsSetAttr:       prometheus.NewDesc(prometheus.BuildFQName(namespace, "event", "vfs_setattr_total"), "VFS setattr() calls.", mountLabels, nil),
+        vfsFlush:         prometheus.NewDesc(prometheus.BuildFQName(namespace, "event", "vfs_flush_total"), "VFS flush() calls.", mountLabels, nil),
+        vfsSync:          prometheus.NewDesc(prometheus.BuildFQName(namespace, "event", "vfs_fsync_total"), "VFS fsync() calls.", mountLabels, nil),
+        vfsLock:          prometheus.NewDesc(prometheus.BuildFQName(namespace, "event", "vfs_lock_total"), "VFS lock() calls.", mountLabels, nil),
+        vfsRelease:       prometheus.NewDesc(prometheus.BuildFQName(namespace, "event", "vfs_release_total"), "VFS release() calls.", mountLabels, nil),
+        congestionWait:   prometheus.NewDesc(prometheus.BuildFQName(namespace, "event", "congestion_wait_total"), "Waits for congestion control to ease.", mountLabels, nil),
+        setAttrTrunc:     prometheus.NewDesc(prometheus.BuildFQName(namespace, "event", "setattr_trunc_total"), "setattr()s that truncated a file.", mountLabels, nil),
+        extendWrite:      prometheus.NewDesc(prometheus.BuildFQName(namespace, "event", "extend_write_total"), "Writes that extended a file's size.", mountLabels, nil),
+        sillyRename:      prometheus.NewDesc(prometheus.BuildFQName(namespace, "event", "silly_rename_total"), "Silly renames due to close-after-delete.", mountLabels, nil),
+        shortRead:        prometheus.NewDesc(prometheus.BuildFQName(namespace, "event", "short_read_total"), "Short reads.", mountLabels, nil),
+        shortWrite:       prometheus.NewDesc(prometheus.BuildFQName(namespace, "event", "short_write_total"), "Short writes.", mountLabels, nil),
+        delay:            prometheus.NewDesc(prometheus.BuildFQName(namespace, "event", "delay_total"), "NFS4ERR_DELAY retries.", mountLabels, nil),
+        pnfsRead:         prometheus.NewDesc(prometheus.BuildFQName(namespace, "event", "pnfs_read_total"), "pNFS reads.", mountLabels, nil),
+        pnfsWrite:        prometheus.NewDesc(prometheus.BuildFQName(namespace, "event", "pnfs_write_total"), "pNFS writes.", mountLabels, nil),
+
+        sourcePort:              prometheus.NewDesc(prometheus.BuildFQName(namespace, "xprt", "source_port"), "Source port used by the RPC transport.", mountLabels, nil),
+        bindCount:               prometheus.NewDesc(prometheus.BuildFQName(namespace, "xprt", "bind_count_total"), "Times the client has had to establish a connection.", mountLabels, nil),
+        connectCount:            prometheus.NewDesc(prometheus.BuildFQName(namespace, "xprt", "connect_count_total"), "Times the client has made a TCP connection.", mountLabels, nil),
+        connectTime:             prometheus.NewDesc(prometheus.BuildFQName(namespace, "xprt", "connect_time_seconds_total"), "Time spent waiting for TCP connections to be established.", mountLabels, nil),
+        idleTime:                prometheus.NewDesc(prometheus.BuildFQName(namespace, "xprt", "idle_time_seconds"), "Time since the transport last carried an RPC request.", mountLabels, nil),
+        rpcSends:                prometheus.NewDesc(prometheus.BuildFQName(namespace, "xprt", "rpc_sends_total"), "RPC requests sent.", mountLabels, nil),
+        rpcReceives:             prometheus.NewDesc(prometheus.BuildFQName(namespace, "xprt", "rpc_receives_total"), "RPC responses received.", mountLabels, nil),
+        badTransactionIDs:       prometheus.NewDesc(prometheus.BuildFQName(namespace, "xprt", "bad_transaction_ids_total"), "Times a transaction ID did not match a request.", mountLabels, nil),
+        requestUtilization:      prometheus.NewDesc(prometheus.BuildFQName(namespace, "xprt", "request_utilization_total"), "Cumulative count of RPC requests for all send slots.", mountLabels, nil),
+        backlogUtilization:      prometheus.NewDesc(prometheus.BuildFQName(namespace, "xprt", "backlog_utilization_total"), "Cumulative count of RPC requests in the backlog queue.", mountLabels, nil),
+        maxSlotsUsed:            prometheus.NewDesc(prometheus.BuildFQName(namespace, "xprt", "max_slots_used"), "Highest number of send slots used concurrently.", mountLabels, nil),
+        sendingQueueUtilization: prometheus.NewDesc(prometheus.BuildFQName(namespace, "xprt", "sending_queue_utilization_total"), "Cumulative count of RPC requests in the sending queue.", mountLabels, nil),
+        pendingQueueUtilization: prometheus.NewDesc(prometheus.BuildFQName(namespace, "xprt", "pending_queue_utilization_total"), "Cumulative count of RPC requests in the pending queue.", mountLabels, nil),
+
+        opRequests:            prometheus.NewDesc(prometheus.BuildFQName(namespace, "op", "requests_total"), "RPC requests for this operation.", opLabels, nil),
+        opTransmissions:       prometheus.NewDesc(prometheus.BuildFQName(namespace, "op", "transmissions_total"), "RPC transmissions for this operation, including retransmissions.", opLabels, nil),
+        opTimeouts:            prometheus.NewDesc(prometheus.BuildFQName(namespace, "op", "timeouts_total"), "Major timeouts for this operation.", opLabels, nil),
+        opBytesSent:           prometheus.NewDesc(prometheus.BuildFQName(namespace, "op", "bytes_sent_total"), "Bytes sent for this operation, including headers.", opLabels, nil),
+        opBytesReceived:       prometheus.NewDesc(prometheus.BuildFQName(namespace, "op", "bytes_received_total"), "Bytes received for this operation, including headers.", opLabels, nil),
+        opTotalQueueTime:      prometheus.NewDesc(prometheus.BuildFQName(namespace, "op", "queue_time_seconds_total"), "Time this operation waited for a free RPC slot.", opLabels, nil),
+        opTotalResponseTime:   prometheus.NewDesc(prometheus.BuildFQName(namespace, "op", "response_time_seconds_total"), "Time between sending this operation's request and receiving its response.", opLabels, nil),
+        opTotalExecutionTime:  prometheus.NewDesc(prometheus.BuildFQName(namespace, "op", "execution_time_seconds_total"), "Time between queuing this operation and receiving its response.", opLabels, nil),
+        opAvgRTT:              prometheus.NewDesc(prometheus.BuildFQName(namespace, "op", "avg_rtt_seconds"), "Average round-trip time: response_time_seconds_total/requests_total.", opLabels, nil),
+        opAvgQueueTime:        prometheus.NewDesc(prometheus.BuildFQName(namespace, "op", "avg_queue_time_seconds"), "Average queue wait: queue_time_seconds_total/requests_total.", opLabels, nil),
+        opAvgExecutionTime:    prometheus.NewDesc(prometheus.BuildFQName(namespace, "op", "avg_execution_time_seconds"), "Average execution time: execution_time_seconds_total/requests_total.", opLabels, nil),
+        opBytesSentPerOp:      prometheus.NewDesc(prometheus.BuildFQName(namespace, "op", "avg_bytes_sent"), "Average bytes sent per request: bytes_sent_total/requests_total.", opLabels, nil),
+        opBytesReceivedPerOp:  prometheus.NewDesc(prometheus.BuildFQName(namespace, "op", "avg_bytes_received"), "Average bytes received per request: bytes_received_total/requests_total.", opLabels, nil),
+        opRetransmissionRatio: prometheus.NewDesc(prometheus.BuildFQName(namespace, "op", "retransmission_ratio"), "(transmissions_total-requests_total)/requests_total for this operation.", opLabels, nil),
+    }
+
+    for _, opt := range opts {
+        opt(c)
+    }
+
+    return c
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+    prometheus.DescribeByCollect(c, ch)
+}
+
+// Collect implements prometheus.Collector. It re-reads c.path on every
+// call; a read or parse failure is reported as an invalid metric rather
+// than panicking the scrape.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+    f, err := os.Open(c.path)
+    if err != nil {
+        ch <- prometheus.NewInvalidMetric(c.age, err)
+        return
+    }
+    defer f.Close()
+
+    mounts, err := nfsstats.Parse(f)
+    if err != nil {
+        ch <- prometheus.NewInvalidMetric(c.age, err)
+        return
+    }
+
+    for _, mount := range mounts {
+        c.collectMount(ch, mount)
+    }
+}
+
+func (c *Collector) collectMount(ch chan<- prometheus.Metric, mount *nfsstats.NFSMount) {
+    version := fmt.Sprintf("%d", mount.Version)
+    labels := []string{mount.Device, mount.Mountpoint, version}
+
+    if mount.Statistics == nil {
+        return
+    }
+    stats := mount.Statistics
+
+    ch <- prometheus.MustNewConstMetric(c.age, prometheus.CounterValue, float64(stats.Age), labels...)
+
+    b := stats.Byte
+    ch <- prometheus.MustNewConstMetric(c.normalReadBytes, prometheus.CounterValue, float64(b.NormalReadBytes), labels...)
+    ch <- prometheus.MustNewConstMetric(c.normalWriteBytes, prometheus.CounterValue, float64(b.NormalWriteBytes), labels...)
+    ch <- prometheus.MustNewConstMetric(c.directReadBytes, prometheus.CounterValue, float64(b.DirectReadBytes), labels...)
+    ch <- prometheus.MustNewConstMetric(c.directWriteBytes, prometheus.CounterValue, float64(b.DirectWriteBytes), labels...)
+    ch <- prometheus.MustNewConstMetric(c.serverReadBytes, prometheus.CounterValue, float64(b.ServerReadBytes), labels...)
+    ch <- prometheus.MustNewConstMetric(c.serverWriteBytes, prometheus.CounterValue, float64(b.ServerWriteBytes), labels...)
+    ch <- prometheus.MustNewConstMetric(c.readPages, prometheus.CounterValue, float64(b.ReadPages), labels...)
+    ch <- prometheus.MustNewConstMetric(c.writePages, prometheus.CounterValue, float64(b.WritePages), labels...)
+
+    e := stats.Event
+    ch <- prometheus.MustNewConstMetric(c.inodeRevalidate, prometheus.CounterValue, float64(e.InodeRevalidate), labels...)
+    ch <- prometheus.MustNewConstMetric(c.dentryRevalidate, prometheus.CounterValue, float64(e.DentryRevalidate), labels...)
+    ch <- prometheus.MustNewConstMetric(c.dataInvalidate, prometheus.CounterValue, float64(e.DataInvalidate), labels...)
+    ch <- prometheus.MustNewConstMetric(c.attrInvalidate, prometheus.CounterValue, float64(e.AttrInvalidate), labels...)
+    ch <- prometheus.MustNewConstMetric(c.vfsOpen, prometheus.CounterValue, float64(e.VFSOpen), labels...)
+    ch <- prometheus.MustNewConstMetric(c.vfsLookup, prometheus.CounterValue, float64(e.VFSLookup), labels...)
+    ch <- prometheus.MustNewConstMetric(c.vfsAccess, prometheus.CounterValue, float64(e.VFSAccess), labels...)
+    ch <- prometheus.MustNewConstMetric(c.vfsUpdatePage, prometheus.CounterValue, float64(e.VFSUpdatePage), labels...)
+    ch <- prometheus.MustNewConstMetric(c.vfsReadPage, prometheus.CounterValue, float64(e.VFSReadPage), labels...)
+    ch <- prometheus.MustNewConstMetric(c.vfsReadPages, prometheus.CounterValue, float64(e.VFSReadPages), labels...)
+    ch <- prometheus.MustNewConstMetric(c.vfsWritePage, prometheus.CounterValue, float64(e.VFSWritePage), labels...)
+    ch <- prometheus.MustNewConstMetric(c.vfsWritePages, prometheus.CounterValue, float64(e.VFSWritePages), labels...)
+    ch <- prometheus.MustNewConstMetric(c.vfsGetDents, prometheus.CounterValue, float64(e.VFSGetDents), labels...)
+    ch <- prometheus.MustNewConstMetric(c.vfsSetAttr, prometheus.CounterValue, float64(e.VFSSetAttr), labels...)
+    ch <- prometheus.MustNewConstMetric(c.vfsFlush, prometheus.CounterValue, float64(e.VFSFlush), labels...)
+    ch <- prometheus.MustNewConstMetric(c.vfsSync, prometheus.CounterValue, float64(e.VFSSync), labels...)
+    ch <- prometheus.MustNewConstMetric(c.vfsLock, prometheus.CounterValue, float64(e.VFSLock), labels...)
+    ch <- prometheus.MustNewConstMetric(c.vfsRelease, prometheus.CounterValue, float64(e.VFSRelease), labels...)
+    ch <- prometheus.MustNewConstMetric(c.congestionWait, prometheus.CounterValue, float64(e.CongestionWait), labels...)
+    ch <- prometheus.MustNewConstMetric(c.setAttrTrunc, prometheus.CounterValue, float64(e.SetAttrTrunc), labels...)
+    ch <- prometheus.MustNewConstMetric(c.extendWrite, prometheus.CounterValue, float64(e.ExtendWrite), labels...)
+    ch <- prometheus.MustNewConstMetric(c.sillyRename, prometheus.CounterValue, float64(e.SillyRename), labels...)
+    ch <- prometheus.MustNewConstMetric(c.shortRead, prometheus.CounterValue, float64(e.ShortRead), labels...)
+    ch <- prometheus.MustNewConstMetric(c.shortWrite, prometheus.CounterValue, float64(e.ShortWrite), labels...)
+    ch <- prometheus.MustNewConstMetric(c.delay, prometheus.CounterValue, float64(e.Delay), labels...)
+    ch <- prometheus.MustNewConstMetric(c.pnfsRead, prometheus.CounterValue, float64(e.PNFSRead), labels...)
+    ch <- prometheus.MustNewConstMetric(c.pnfsWrite, prometheus.CounterValue, float64(e.PNFSWrite), labels...)
+
+    t := stats.Transport
+    ch <- prometheus.MustNewConstMetric(c.sourcePort, prometheus.GaugeValue, float64(t.SourcePort), labels...)
+    ch <- prometheus.MustNewConstMetric(c.bindCount, prometheus.CounterValue, float64(t.BindCount), labels...)
+    ch <- prometheus.MustNewConstMetric(c.connectCount, prometheus.CounterValue, float64(t.ConnectCount), labels...)
+    ch <- prometheus.MustNewConstMetric(c.connectTime, prometheus.CounterValue, float64(t.ConnectTime)/1000, labels...)
+    ch <- prometheus.MustNewConstMetric(c.idleTime, prometheus.GaugeValue, float64(t.IdleTime)/1000, labels...)
+    ch <- prometheus.MustNewConstMetric(c.rpcSends, prometheus.CounterValue, float64(t.RPCSends), labels...)
+    ch <- prometheus.MustNewConstMetric(c.rpcReceives, prometheus.CounterValue, float64(t.RPCReceives), labels...)
+    ch <- prometheus.MustNewConstMetric(c.badTransactionIDs, prometheus.CounterValue, float64(t.BadTransactionIDs), labels...)
+    ch <- prometheus.MustNewConstMetric(c.requestUtilization, prometheus.CounterValue, float64(t.RequestUtilization), labels...)
+    ch <- prometheus.MustNewConstMetric(c.backlogUtilization, prometheus.CounterValue, float64(t.BacklogUtilization), labels...)
+    ch <- prometheus.MustNewConstMetric(c.maxSlotsUsed, prometheus.GaugeValue, float64(t.MaxSlotsUsed), labels...)
+    ch <- prometheus.MustNewConstMetric(c.sendingQueueUtilization, prometheus.CounterValue, float64(t.SendingQueueUtilization), labels...)
+    ch <- prometheus.MustNewConstMetric(c.pendingQueueUtilization, prometheus.CounterValue, float64(t.PendingQueueUtilization), labels...)
+
+    for op, counters := range stats.Operation {
+        opLabels := append(append([]string{}, labels...), op)
+
+        ch <- prometheus.MustNewConstMetric(c.opRequests, prometheus.CounterValue, float64(counters.Requests), opLabels...)
+        ch <- prometheus.MustNewConstMetric(c.opTransmissions, prometheus.CounterValue, float64(counters.Transmissions), opLabels...)
+        ch <- prometheus.MustNewConstMetric(c.opTimeouts, prometheus.CounterValue, float64(counters.Timeouts), opLabels...)
+        ch <- prometheus.MustNewConstMetric(c.opBytesSent, prometheus.CounterValue, float64(counters.BytesSent), opLabels...)
+        ch <- prometheus.MustNewConstMetric(c.opBytesReceived, prometheus.CounterValue, float64(counters.BytesReceived), opLabels...)
+        ch <- prometheus.MustNewConstMetric(c.opTotalQueueTime, prometheus.CounterValue, float64(counters.TotalQueueTime)/1000, opLabels...)
+        ch <- prometheus.MustNewConstMetric(c.opTotalResponseTime, prometheus.CounterValue, float64(counters.TotalResponseTime)/1000, opLabels...)
+        ch <- prometheus.MustNewConstMetric(c.opTotalExecutionTime, prometheus.CounterValue, float64(counters.TotalExecutionTime)/1000, opLabels...)
+
+        if counters.Requests > 0 {
+            requests := float64(counters.Requests)
+            ch <- prometheus.MustNewConstMetric(c.opAvgRTT, prometheus.GaugeValue, float64(counters.TotalResponseTime)/1000/requests, opLabels...)
+            ch <- prometheus.MustNewConstMetric(c.opAvgQueueTime, prometheus.GaugeValue, float64(counters.TotalQueueTime)/1000/requests, opLabels...)
+            ch <- prometheus.MustNewConstMetric(c.opAvgExecutionTime, prometheus.GaugeValue, float64(counters.TotalExecutionTime)/1000/requests, opLabels...)
+            ch <- prometheus.MustNewConstMetric(c.opBytesSentPerOp, prometheus.GaugeValue, float64(counters.BytesSent)/requests, opLabels...)
+            ch <- prometheus.MustNewConstMetric(c.opBytesReceivedPerOp, prometheus.GaugeValue, float64(counters.BytesReceived)/requests, opLabels...)
+        }
+
+        if counters.Requests > 0 {
+            ratio := 0.0
+            if counters.Transmissions > counters.Requests {
+                ratio = float64(counters.Transmissions-counters.Requests) / float64(counters.Requests)
+            }
+            ch <- prometheus.MustNewConstMetric(c.opRetransmissionRatio, prometheus.GaugeValue, ratio, opLabels...)
+        }
+    }
+}
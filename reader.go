@@ -0,0 +1,153 @@
+package nfsstats
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "os"
+    "time"
+)
+
+// Reader periodically re-opens and re-parses a mountstats file, delivering
+// a snapshot of the parsed mounts on the channel returned by Subscribe
+// every interval, until Close is called.
+type Reader struct {
+    path string
+    interval time.Duration
+
+    ctx context.Context
+    cancel context.CancelFunc
+
+    snapshots chan []*NFSMount
+    errs chan error
+}
+
+// NewReader returns a Reader that polls /proc/[pid]/mountstats every
+// interval.
+func NewReader(pid int, interval time.Duration) *Reader {
+    return NewReaderForPath(fmt.Sprintf("/proc/%d/mountstats", pid), interval)
+}
+
+// NewSelfReader returns a Reader that polls /proc/self/mountstats every
+// interval.
+func NewSelfReader(interval time.Duration) *Reader {
+    return NewReaderForPath("/proc/self/mountstats", interval)
+}
+
+// NewReaderForPath returns a Reader that polls the mountstats file at path
+// every interval. It exists alongside NewReader/NewSelfReader so callers
+// (and tests) can point a Reader at a fixture file instead of a real
+// /proc entry.
+func NewReaderForPath(path string, interval time.Duration) *Reader {
+    ctx, cancel := context.WithCancel(context.Background())
+
+    r := &Reader {
+        path: path,
+        interval: interval,
+        ctx: ctx,
+        cancel: cancel,
+        snapshots: make(chan []*NFSMount),
+        errs: make(chan error, 1),
+    }
+
+    go r.run()
+
+    return r
+}
+
+// Subscribe returns the channel on which snapshots are delivered. Each
+// snapshot is the result of re-parsing the mountstats file on one polling
+// tick. A slow receiver blocks the Reader's polling loop until it catches
+// up or the Reader is closed.
+func (r *Reader) Subscribe() <-chan []*NFSMount {
+    return r.snapshots
+}
+
+// Errs returns a channel on which read/parse failures from the polling
+// loop are reported: os.Open failures (a deleted mount, a permission
+// change, the pid exiting) and, via a lenient-mode ParseWithOptions, the
+// malformed lines of an otherwise-parseable mountstats file joined into
+// a single error. A lenient parse error does not stop the corresponding
+// snapshot from being delivered on Subscribe, so one malformed mount
+// does not blank out metrics for every healthy mount on the same tick.
+// The channel is buffered to depth 1 and a send that would block is
+// dropped in favor of the newer error, so a caller that never reads
+// Errs still only pays for a single retained error, not an unbounded
+// backlog. It is closed once the polling loop has exited.
+func (r *Reader) Errs() <-chan error {
+    return r.errs
+}
+
+// Close stops the Reader's polling loop and waits for it to exit. A
+// snapshot still in flight is discarded, so once Close returns the channel
+// returned by Subscribe delivers nothing further and is closed. It is safe
+// to call Close more than once.
+func (r *Reader) Close() error {
+    r.cancel()
+    for range r.snapshots {}
+    return nil
+}
+
+func (r *Reader) run() {
+    defer close(r.snapshots)
+    defer close(r.errs)
+
+    ticker := time.NewTicker(r.interval)
+    defer ticker.Stop()
+
+    for {
+        select {
+            case <-r.ctx.Done():
+                return
+
+            case <-ticker.C:
+                mounts, err := r.read()
+                if err != nil {
+                    r.reportErr(err)
+                }
+                if mounts == nil {
+                    continue
+                }
+
+                select {
+                    case r.snapshots <- mounts:
+                    case <-r.ctx.Done():
+                        return
+                }
+        }
+    }
+}
+
+// reportErr delivers err on r.errs without blocking the polling loop. If
+// the channel's single slot is already occupied by a previous error that
+// no one has read yet, it is dropped in favor of err.
+func (r *Reader) reportErr(err error) {
+    select {
+        case r.errs <- err:
+        default:
+            select {
+                case <-r.errs:
+                default:
+            }
+            select {
+                case r.errs <- err:
+                default:
+            }
+    }
+}
+
+// read re-opens and re-parses r.path in ParseModeLenient, so a malformed
+// line in one mount doesn't discard the mounts that parsed cleanly. Any
+// collected parse errors are joined into a single error and returned
+// alongside the (still usable) mounts.
+func (r *Reader) read() ([]*NFSMount, error) {
+    f, err := os.Open(r.path)
+    if err != nil { return nil, err }
+    defer f.Close()
+
+    mounts, errs, err := ParseWithOptions(f, ParseOptions{Mode: ParseModeLenient})
+    if err != nil { return mounts, err }
+    if len(errs) > 0 { return mounts, errors.Join(errs...) }
+
+    return mounts, nil
+}
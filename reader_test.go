@@ -0,0 +1,103 @@
+package nfsstats
+
+import (
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+    "time"
+)
+
+func TestReader_Subscribe(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "mountstats")
+    body := mountstatsFixture("1.1", "xprt: tcp 0 1 2 3 4 5 6 7 8 9 10 11 12", "READ: 1 2 3 4 5 6 7 8")
+    if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+        t.Fatalf("WriteFile: %v", err)
+    }
+
+    r := NewReaderForPath(path, 10*time.Millisecond)
+    defer r.Close()
+
+    select {
+        case mounts, ok := <-r.Subscribe():
+            if !ok {
+                t.Fatal("Subscribe channel closed before delivering a snapshot")
+            }
+            if len(mounts) != 1 {
+                t.Fatalf("got %d mounts, want 1", len(mounts))
+            }
+        case err := <-r.Errs():
+            t.Fatalf("unexpected error: %v", err)
+        case <-time.After(time.Second):
+            t.Fatal("timed out waiting for a snapshot")
+    }
+
+    if err := r.Close(); err != nil {
+        t.Fatalf("Close: %v", err)
+    }
+
+    // Close is idempotent.
+    if err := r.Close(); err != nil {
+        t.Fatalf("second Close: %v", err)
+    }
+
+    select {
+        case _, ok := <-r.Subscribe():
+            if ok {
+                t.Fatal("Subscribe delivered a snapshot after Close")
+            }
+        case <-time.After(time.Second):
+            t.Fatal("timed out waiting for Subscribe to close after Close")
+    }
+}
+
+func TestReader_ErrsOnOpenFailure(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "does-not-exist")
+
+    r := NewReaderForPath(path, 10*time.Millisecond)
+    defer r.Close()
+
+    select {
+        case err := <-r.Errs():
+            if err == nil {
+                t.Fatal("got nil error, want an os.Open failure")
+            }
+        case mounts := <-r.Subscribe():
+            t.Fatalf("unexpected snapshot from a file that doesn't exist: %v", mounts)
+        case <-time.After(time.Second):
+            t.Fatal("timed out waiting for an error")
+    }
+}
+
+func TestReader_ErrsDoesNotBlockSnapshotsOnLenientParseError(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "mountstats")
+    body := mountstatsFixture("1.1", "xprt: tcp 0 1 2 3 4 5 6 7 8 9 10 11 12", "READ: 1 2 3 4 5 6 7 8")
+    // Corrupt the bytes line (too few fields) so the lenient parse
+    // reports an error but still returns the mount.
+    body = strings.Replace(body, "bytes: 1 2 3 4 5 6 7 8", "bytes: 1 2 3 4 5", 1)
+    if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+        t.Fatalf("WriteFile: %v", err)
+    }
+
+    r := NewReaderForPath(path, 10*time.Millisecond)
+    defer r.Close()
+
+    var gotErr, gotSnapshot bool
+    deadline := time.After(time.Second)
+    for !gotErr || !gotSnapshot {
+        select {
+            case err := <-r.Errs():
+                if err == nil {
+                    t.Fatal("got nil error on Errs channel")
+                }
+                gotErr = true
+            case mounts := <-r.Subscribe():
+                if len(mounts) != 1 {
+                    t.Fatalf("got %d mounts, want 1", len(mounts))
+                }
+                gotSnapshot = true
+            case <-deadline:
+                t.Fatalf("timed out waiting for both an error and a snapshot (err=%v, snapshot=%v)", gotErr, gotSnapshot)
+        }
+    }
+}